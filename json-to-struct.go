@@ -1,6 +1,9 @@
-// cppjson generates cpp struct defintions from JSON documents.
+// cppjson generates cpp struct defintions from JSON or YAML documents.
 //
-// Reads from stdin and prints to stdout
+// Reads from stdin and prints to stdout. The -format flag selects the input
+// format (json, yaml, or auto to sniff it); it defaults to auto. Set
+// -out-header and -out-source together to write a header/source pair
+// instead of printing a single combined blob.
 //
 // Example:
 // 	curl -s https://api.github.com/repos/kyokomi/cppjson | cppjson -name=Repository
@@ -25,7 +28,6 @@
 package main
 
 import (
-	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
@@ -33,22 +35,37 @@ import (
 	"reflect"
 	"sort"
 	"strings"
-	"unicode"
 )
 
-const templateStruct = "%s \n};\n"
-
 var (
-	name = flag.String("name", "Foo", "the name of the struct")
-	pkg  = flag.String("pkg", "main", "the name of the package for the generated code")
+	name          = flag.String("name", "Foo", "the name of the struct")
+	pkg           = flag.String("pkg", "main", "the name of the package for the generated code")
+	format        = flag.String("format", "auto", "input format: json, yaml, or auto")
+	serializer    = flag.String("serializer", "none", "serialization helpers to emit: nlohmann, rapidjson, or none")
+	namespace     = flag.String("namespace", "", "C++ namespace to wrap the generated code in, if set")
+	style         = flag.String("style", "PascalCase", "field naming style: PascalCase, camelCase, or snake_case")
+	arrayFallback = flag.String("array-fallback", "nlohmann", "type to fall back to for empty or heterogeneous arrays: nlohmann or any")
+	forceFloats   = flag.Bool("force-floats", false, "disable integer inference and always emit double for numbers")
+	outHeader     = flag.String("out-header", "", "write struct definitions to this header file instead of stdout (requires -out-source)")
+	outSource     = flag.String("out-source", "", "write serializer implementations to this source file instead of stdout (requires -out-header)")
+	guardStyle    = flag.String("guard-style", "pragma", "header include guard style: pragma or ifndef")
 )
 
-// Given a JSON string representation of an object and a name structName,
-// attemp to generate a struct definition
-func generate(input io.Reader, structName string) ([]byte, error) {
-	var iresult interface{}
+// generated holds the pieces generate produces: the struct definitions and
+// (if -serializer is set) their serialization helpers, kept apart so the
+// caller can either print them combined or split them across a header and
+// source file.
+type generated struct {
+	structDefs  string
+	serializers string
+}
+
+// Given an input document and a name structName, attemp to generate a
+// struct definition. parse decodes the raw input according to -format.
+func generate(input io.Reader, structName string, parse Parser) (*generated, error) {
 	var result map[string]interface{}
-	if err := json.NewDecoder(input).Decode(&iresult); err != nil {
+	iresult, err := parse(input)
+	if err != nil {
 		return nil, err
 	}
 
@@ -65,146 +82,153 @@ func generate(input io.Reader, structName string) ([]byte, error) {
 		return nil, fmt.Errorf("unexpected type: %T", iresult)
 	}
 
-	src := fmt.Sprintf(templateStruct,
-		generateTypes(structName, result, 0))
-//	formatted, err := format.Source([]byte(src))
-//	if err != nil {
-//		err = fmt.Errorf("error formatting: %s, was formatting\n%s", err, src)
-//	}
-	return []byte(src), nil
+	var structs []structInfo
+	structDefs := generateTypes(structName, result, 0, &structs, nil)
+	serializers := emitSerializers(*serializer, structs)
+
+	return &generated{structDefs: structDefs, serializers: serializers}, nil
 }
 
-// Generate go struct entries for a map[string]interface{} structure
-func generateTypes(structName string, obj map[string]interface{}, depth int) string {
-	structure := fmt.Sprintf("\nstruct %s {", structName)
+// combined renders g as a single self-contained blob, for the default
+// (unsplit) stdout output.
+func (g *generated) combined() []byte {
+	body := g.structDefs
+	if g.serializers != "" {
+		body += g.serializers
+	}
+	if *namespace != "" {
+		body = wrapNamespace(body)
+	}
+	if g.serializers != "" {
+		body = serializerInclude(*serializer) + body
+	}
+	return []byte(body)
+}
 
+func wrapNamespace(src string) string {
+	return fmt.Sprintf("namespace %s {\n%s\n} // namespace %s\n", *namespace, src, *namespace)
+}
+
+// Generate cpp struct entries for a map[string]interface{} structure.
+// structs accumulates every struct emitted, in definition order, so
+// serializers can be generated for them afterwards. The returned string is
+// a complete, self-contained definition: any nested struct types it
+// references are hoisted and emitted immediately before it, since C++ can't
+// forward-declare an anonymous nested type.
+func generateTypes(structName string, obj map[string]interface{}, depth int, structs *[]structInfo, fieldSamples map[string][]interface{}) string {
 	keys := make([]string, 0, len(obj))
 	for key := range obj {
 		keys = append(keys, key)
 	}
 	sort.Strings(keys)
 
+	var nested []string
+	structure := fmt.Sprintf("\nstruct %s {", structName)
+	info := structInfo{name: structName}
+
 	for _, key := range keys {
 		value := obj[key]
-		valueType := typeForValue(key, value)
-
-		//If a nested value, recurse
-		switch value := value.(type) {
-		case []map[string]interface{}:
-			valueType = "[]" + generateTypes(key, value[0], depth+1) + "\n};"
-		case map[string]interface{}:
-			valueType = generateTypes(key, value, depth+1) + "\n};"
+		samples := fieldSamples[key]
+		if samples == nil {
+			samples = []interface{}{value}
 		}
 
-		fieldName := fmtFieldName(key)
-		structure += fmt.Sprintf("\n%s %s;",
-			valueType,
-			fieldName)
+		fieldType, pre := typeForValue(key, value, depth, structs, samples)
+		nested = append(nested, pre...)
+
+		fieldName := fmtFieldName(key, *style)
+		structure += fmt.Sprintf("\n%s %s;", fieldType, fieldName)
+
+		info.fields = append(info.fields, structField{
+			key:     key,
+			name:    fieldName,
+			cppType: fieldType,
+		})
 	}
-	return structure
+
+	*structs = append(*structs, info)
+	return strings.Join(nested, "") + structure + "\n};"
 }
 
-var uppercaseFixups = map[string]bool{"id": true, "url": true}
-
-func isSeparator(r rune) bool {
-	// ASCII alphanumerics and underscore are not separators
-	if r <= 0x7F {
-		switch {
-		case '0' <= r && r <= '9':
-			return false
-		case 'a' <= r && r <= 'z':
-			return false
-		case 'A' <= r && r <= 'Z':
-			return false
-		case r == '_':
-			return false
-		}
-		return true
-	}
-	// Letters and digits are not separators
-	if unicode.IsLetter(r) || unicode.IsDigit(r) {
-		return false
-	}
-	// Otherwise, all we can do for now is treat spaces as separators.
-	return unicode.IsSpace(r)
+var cppTypeMapping = map[string]string{
+	"string": "std::string",
 }
 
-func Camel(s string) string {
-	// Use a closure here to remember state.
-	// Hackish but effective. Depends on Map scanning in order and calling
-	// the closure once per rune.
-	prev := ' '
-	return strings.Map(
-			func(r rune) rune {
-				if isSeparator(prev) {
-					prev = r
-					return unicode.ToLower(r)
-				}
-				prev = r
-				return r
-			},
-			s)
+// typeForValue resolves the C++ type to use for a struct field and any
+// nested struct definitions that must be hoisted before the enclosing
+// struct to satisfy them. samples holds every value seen for this key
+// across repeated occurrences (e.g. every element of an enclosing array of
+// objects), used to widen numeric fields consistently.
+func typeForValue(key string, value interface{}, depth int, structs *[]structInfo, samples []interface{}) (fieldType string, nested []string) {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		structName := uniqueStructName(pascalCase(key), structs)
+		return structName, []string{generateTypes(structName, value, depth+1, structs, nil)}
+	case []interface{}:
+		return typeForArray(key, value, depth, structs)
+	case nil:
+		return "nlohmann::json", nil
+	case float64, int, int64:
+		return numberFieldType(samples), nil
+	default:
+		return cppPrimitiveType(value), nil
+	}
 }
 
-// fmtFieldName formats a string as a struct key
-//
-// Example:
-// 	fmtFieldName("foo_id")
-// Output: FooID
-func fmtFieldName(s string) string {
-	parts := strings.Split(s, "_")
-	for i := range parts {
-		parts[i] = strings.Title(parts[i])
-	}
-	if len(parts) > 0 {
-		last := parts[len(parts)-1]
-		if uppercaseFixups[strings.ToLower(last)] {
-			parts[len(parts)-1] = strings.ToUpper(last)
-		}
+// typeForArray resolves the element type of a JSON/YAML array field. Arrays
+// of objects hoist a singularized element struct (e.g. "items" -> "Item")
+// built from samples gathered across every element; arrays of numbers are
+// widened the same way a repeated scalar field would be; other primitive
+// arrays map straight to std::vector<T>; empty or heterogeneous arrays fall
+// back to -array-fallback.
+func typeForArray(key string, values []interface{}, depth int, structs *[]structInfo) (string, []string) {
+	if len(values) == 0 {
+		return arrayFallbackType(), nil
 	}
-	assembled := strings.Join(parts, "")
-	runes := []rune(assembled)
-	for i, c := range runes {
-		ok := unicode.IsLetter(c) || unicode.IsDigit(c)
-		if i == 0 {
-			ok = unicode.IsLetter(c)
-		}
-		if !ok {
-			runes[i] = '_'
+
+	elemType := reflect.TypeOf(values[0])
+	for _, v := range values {
+		if reflect.TypeOf(v) != elemType {
+			return arrayFallbackType(), nil
 		}
 	}
-	return Camel(string(runes))
-}
 
-var cppTypeMapping = map[string]string{
-	"string": "std::string",
-	"float64": "float",
-	"int64": "int64_t",
+	if obj, ok := values[0].(map[string]interface{}); ok {
+		elemName := uniqueStructName(elemTypeName(key), structs)
+		samples := collectFieldSamples(values)
+		return fmt.Sprintf("std::vector<%s>", elemName), []string{generateTypes(elemName, obj, depth+1, structs, samples)}
+	}
+
+	if isNumericValue(values[0]) {
+		return fmt.Sprintf("std::vector<%s>", numberFieldType(values)), nil
+	}
+
+	return fmt.Sprintf("std::vector<%s>", cppPrimitiveType(values[0])), nil
 }
 
-// generate an appropriate struct type entry
-func typeForValue(key string, value interface{}) string {
-	//Check if this is an array
-	if objects, ok := value.([]interface{}); ok {
-		types := make(map[reflect.Type]bool, 0)
-		for _, o := range objects {
-			types[reflect.TypeOf(o)] = true
+// collectFieldSamples gathers, per key, every value seen for that key
+// across all elements of an array of objects.
+func collectFieldSamples(values []interface{}) map[string][]interface{} {
+	samples := make(map[string][]interface{})
+	for _, v := range values {
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			continue
 		}
-		if len(types) == 1 {
-			return typeForValue(key, objects[0]) + fmt.Sprintf("\nstd::vector<%s>", key)
+		for key, val := range obj {
+			samples[key] = append(samples[key], val)
 		}
-		return "[]interface{}"
-	} else if object, ok := value.(map[string]interface{}); ok {
-		return generateTypes(key, object, 0) + "\n};"
-	} else if reflect.TypeOf(value) == nil {
-		return "interface{}"
 	}
+	return samples
+}
 
-	convert := cppTypeMapping[reflect.TypeOf(value).Name()]
-	if convert != "" {
-		return convert
+// arrayFallbackType returns the C++ type used for empty or heterogeneous
+// arrays, per -array-fallback.
+func arrayFallbackType() string {
+	if *arrayFallback == "any" {
+		return "std::vector<std::any>"
 	}
-	return reflect.TypeOf(value).Name()
+	return "std::vector<nlohmann::json>"
 }
 
 // Return true if os.Stdin appears to be interactive
@@ -225,10 +249,35 @@ func main() {
 		os.Exit(1)
 	}
 
-	if output, err := generate(os.Stdin, *name); err != nil {
+	parse, err := parserForFormat(*format)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	gen, err := generate(os.Stdin, *name, parse)
+	if err != nil {
 		fmt.Fprintln(os.Stderr, "error parsing", err)
 		os.Exit(1)
-	} else {
-		fmt.Print(string(output))
 	}
+
+	if *outHeader != "" || *outSource != "" {
+		if *outHeader == "" || *outSource == "" {
+			fmt.Fprintln(os.Stderr, "-out-header and -out-source must be set together")
+			os.Exit(1)
+		}
+
+		header, source := gen.headerAndSource(*outHeader, *guardStyle)
+		if err := os.WriteFile(*outHeader, header, 0644); err != nil {
+			fmt.Fprintln(os.Stderr, "error writing header:", err)
+			os.Exit(1)
+		}
+		if err := os.WriteFile(*outSource, source, 0644); err != nil {
+			fmt.Fprintln(os.Stderr, "error writing source:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	fmt.Print(string(gen.combined()))
 }
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTypeForArray(t *testing.T) {
+	tests := []struct {
+		name         string
+		key          string
+		values       []interface{}
+		wantType     string
+		wantNested   bool
+		wantStructOf string // struct name expected in the hoisted definition, if wantNested
+	}{
+		{
+			name:     "ints",
+			key:      "counts",
+			values:   []interface{}{1, 2, 3},
+			wantType: "std::vector<int32_t>",
+		},
+		{
+			name:     "strings",
+			key:      "tags",
+			values:   []interface{}{"a", "b", "c"},
+			wantType: "std::vector<std::string>",
+		},
+		{
+			name:         "objects",
+			key:          "items",
+			values:       []interface{}{map[string]interface{}{"name": "a"}},
+			wantType:     "std::vector<Item>",
+			wantNested:   true,
+			wantStructOf: "struct Item {",
+		},
+		{
+			name:     "mixed",
+			key:      "mixed",
+			values:   []interface{}{1, "a"},
+			wantType: "std::vector<nlohmann::json>",
+		},
+		{
+			name:     "empty",
+			key:      "empty",
+			values:   []interface{}{},
+			wantType: "std::vector<nlohmann::json>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var structs []structInfo
+			gotType, nested := typeForArray(tt.key, tt.values, 0, &structs)
+
+			if gotType != tt.wantType {
+				t.Errorf("typeForArray(%q, %v) type = %q, want %q", tt.key, tt.values, gotType, tt.wantType)
+			}
+
+			if tt.wantNested && len(nested) == 0 {
+				t.Fatalf("typeForArray(%q, %v) returned no nested struct, want one", tt.key, tt.values)
+			}
+			if !tt.wantNested && len(nested) != 0 {
+				t.Errorf("typeForArray(%q, %v) returned nested struct %v, want none", tt.key, tt.values, nested)
+			}
+			if tt.wantStructOf != "" && !strings.Contains(strings.Join(nested, ""), tt.wantStructOf) {
+				t.Errorf("typeForArray(%q, %v) nested = %v, want it to contain %q", tt.key, tt.values, nested, tt.wantStructOf)
+			}
+		})
+	}
+}
+
+func TestTypeForValueNestedObjectNaming(t *testing.T) {
+	t.Run("sanitizes invalid identifiers", func(t *testing.T) {
+		var structs []structInfo
+		fieldType, _ := typeForValue("my-config", map[string]interface{}{"a": 1}, 0, &structs, nil)
+		if fieldType != "My_config" {
+			t.Errorf("typeForValue(%q, ...) type = %q, want %q", "my-config", fieldType, "My_config")
+		}
+	})
+
+	t.Run("dedupes same key reused at different nesting depths", func(t *testing.T) {
+		var structs []structInfo
+		outerType, outerNested := typeForValue("a", map[string]interface{}{"x": 1}, 0, &structs, nil)
+		innerType, innerNested := typeForValue("a", map[string]interface{}{"y": 2}, 0, &structs, nil)
+
+		if outerType != "A" {
+			t.Errorf("outer typeForValue type = %q, want %q", outerType, "A")
+		}
+		if innerType == outerType {
+			t.Errorf("inner typeForValue type = %q, want a name distinct from outer %q", innerType, outerType)
+		}
+		if !strings.Contains(strings.Join(outerNested, ""), "struct A {") {
+			t.Errorf("outer nested = %v, want it to contain %q", outerNested, "struct A {")
+		}
+		if !strings.Contains(strings.Join(innerNested, ""), "struct "+innerType+" {") {
+			t.Errorf("inner nested = %v, want it to contain %q", innerNested, "struct "+innerType+" {")
+		}
+	})
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+// initialisms is the set of common initialisms that should be rendered in
+// all caps rather than title case, e.g. "foo_id" -> "FooID" not "FooId".
+// Matches the set used by golint, plus a few C++-flavoured additions.
+var initialisms = map[string]bool{
+	"API": true, "ASCII": true, "CPU": true, "CSS": true, "DB": true,
+	"DNS": true, "EOF": true, "GUID": true, "HTML": true, "HTTP": true,
+	"HTTPS": true, "ID": true, "IP": true, "JSON": true, "LHS": true,
+	"NTP": true, "QPS": true, "RAM": true, "RHS": true, "RPC": true,
+	"SLA": true, "SMTP": true, "SSH": true, "TLS": true, "TTL": true,
+	"UI": true, "UID": true, "UUID": true, "URI": true, "URL": true,
+	"UTF8": true, "VM": true, "XML": true,
+}
+
+// fmtFieldName formats a JSON/YAML key as a C++ struct field name in the
+// given style: PascalCase, camelCase, or snake_case. Any other value (and
+// the default) falls back to PascalCase.
+//
+// Example:
+// 	fmtFieldName("foo_id", "PascalCase")
+// Output: FooID
+func fmtFieldName(s string, style string) string {
+	parts := strings.Split(s, "_")
+	cased := make([]string, len(parts))
+	for i, part := range parts {
+		cased[i] = titleOrInitialism(part)
+	}
+
+	var joined string
+	switch style {
+	case "camelCase":
+		joined = strings.ToLower(parts[0])
+		for _, part := range cased[1:] {
+			joined += part
+		}
+	case "snake_case":
+		lower := make([]string, len(parts))
+		for i, part := range parts {
+			lower[i] = strings.ToLower(part)
+		}
+		joined = strings.Join(lower, "_")
+	default: // PascalCase
+		joined = strings.Join(cased, "")
+	}
+
+	return sanitizeIdentifier(joined)
+}
+
+// titleOrInitialism title-cases a single word, or upper-cases it in full if
+// it matches a known initialism. Unlike strings.Title, it only ever
+// capitalizes the word's first rune: a raw JSON/YAML key can contain
+// non-letter runes (e.g. "my-config"), and strings.Title would treat those
+// as word boundaries too and capitalize the letters that follow them.
+func titleOrInitialism(word string) string {
+	if initialisms[strings.ToUpper(word)] {
+		return strings.ToUpper(word)
+	}
+	lower := strings.ToLower(word)
+	if lower == "" {
+		return lower
+	}
+	runes := []rune(lower)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+// pascalCase derives a valid, PascalCase C++ type name from a JSON/YAML
+// key, e.g. "my-config" -> "MyConfig", "class" -> "Class". Always
+// PascalCase, independent of -style, since it names a type rather than a
+// field, and always a valid identifier, unlike the raw key.
+func pascalCase(key string) string {
+	var joined string
+	for _, part := range strings.Split(key, "_") {
+		joined += titleOrInitialism(part)
+	}
+	return sanitizeIdentifier(joined)
+}
+
+// elemTypeName derives the singular struct type name for an array field,
+// e.g. "items" -> "Item", "photo_urls" -> "PhotoUrl".
+func elemTypeName(key string) string {
+	parts := strings.Split(key, "_")
+	parts[len(parts)-1] = singularize(parts[len(parts)-1])
+	return pascalCase(strings.Join(parts, "_"))
+}
+
+// singularize strips a common English plural suffix from word. It's a
+// heuristic, not a dictionary lookup, but covers the common JSON array
+// key shapes ("items", "categories", "statuses").
+func singularize(word string) string {
+	lower := strings.ToLower(word)
+	switch {
+	case strings.HasSuffix(lower, "ies") && len(word) > 3:
+		return word[:len(word)-3] + "y"
+	case strings.HasSuffix(lower, "ses"), strings.HasSuffix(lower, "xes"),
+		strings.HasSuffix(lower, "ches"), strings.HasSuffix(lower, "shes"):
+		return word[:len(word)-2]
+	case strings.HasSuffix(lower, "s") && !strings.HasSuffix(lower, "ss"):
+		return word[:len(word)-1]
+	default:
+		return word
+	}
+}
+
+// sanitizeIdentifier replaces any rune that isn't valid in a C++ identifier
+// position with an underscore.
+func sanitizeIdentifier(s string) string {
+	runes := []rune(s)
+	for i, c := range runes {
+		ok := unicode.IsLetter(c) || unicode.IsDigit(c)
+		if i == 0 {
+			ok = unicode.IsLetter(c)
+		}
+		if !ok {
+			runes[i] = '_'
+		}
+	}
+	return string(runes)
+}
@@ -0,0 +1,45 @@
+package main
+
+import "testing"
+
+func TestFmtFieldName(t *testing.T) {
+	tests := []struct {
+		name  string
+		key   string
+		style string
+		want  string
+	}{
+		{"PascalCase with initialism", "foo_id", "PascalCase", "FooID"},
+		{"PascalCase default style", "foo_id", "", "FooID"},
+		{"camelCase with initialism", "foo_id", "camelCase", "fooID"},
+		{"snake_case lowercases initialisms", "photo_url", "snake_case", "photo_url"},
+		{"snake_case single word", "name", "snake_case", "name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fmtFieldName(tt.key, tt.style); got != tt.want {
+				t.Errorf("fmtFieldName(%q, %q) = %q, want %q", tt.key, tt.style, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPascalCase(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"my-config", "My_config"},
+		{"class", "Class"},
+		{"foo_id", "FooID"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := pascalCase(tt.key); got != tt.want {
+				t.Errorf("pascalCase(%q) = %q, want %q", tt.key, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import "math"
+
+// isNumericValue reports whether v is one of the numeric leaf types the
+// decoders can produce (json.Decode always uses float64; YAML can produce
+// int or int64 for scalar integers).
+func isNumericValue(v interface{}) bool {
+	switch v.(type) {
+	case float64, int, int64:
+		return true
+	default:
+		return false
+	}
+}
+
+// numberFieldType determines the narrowest C++ numeric type that can hold
+// every sample seen for a field, widening as needed. -force-floats disables
+// inference entirely and always emits "double".
+func numberFieldType(samples []interface{}) string {
+	if *forceFloats {
+		return "double"
+	}
+
+	floats := make([]float64, 0, len(samples))
+	for _, v := range samples {
+		switch n := v.(type) {
+		case float64:
+			floats = append(floats, n)
+		case int:
+			floats = append(floats, float64(n))
+		case int64:
+			floats = append(floats, float64(n))
+		}
+	}
+	if len(floats) == 0 {
+		return "double"
+	}
+	return numericCppType(floats)
+}
+
+// numericCppType picks the smallest of int32_t/int64_t/uint32_t/uint64_t/
+// double that can represent every value in samples. Any non-integral
+// sample forces "double".
+func numericCppType(samples []float64) string {
+	min, max := samples[0], samples[0]
+	for _, f := range samples {
+		if f != math.Trunc(f) || math.IsInf(f, 0) || math.IsNaN(f) {
+			return "double"
+		}
+		if f < min {
+			min = f
+		}
+		if f > max {
+			max = f
+		}
+	}
+
+	if min < 0 {
+		if min >= math.MinInt32 && max <= math.MaxInt32 {
+			return "int32_t"
+		}
+		if min >= math.MinInt64 && max <= math.MaxInt64 {
+			return "int64_t"
+		}
+		return "double"
+	}
+
+	switch {
+	case max <= math.MaxInt32:
+		return "int32_t"
+	case max <= math.MaxUint32:
+		return "uint32_t"
+	case max <= math.MaxInt64:
+		return "int64_t"
+	case max <= math.MaxUint64:
+		return "uint64_t"
+	default:
+		return "double"
+	}
+}
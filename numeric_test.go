@@ -0,0 +1,35 @@
+package main
+
+import "testing"
+
+func TestNumericCppType(t *testing.T) {
+	tests := []struct {
+		name    string
+		samples []float64
+		want    string
+	}{
+		{"small ints", []float64{1, 2, 3}, "int32_t"},
+		{"negative int", []float64{-1, 0, 5}, "int32_t"},
+		{"mixed int and float", []float64{1, 2.5, 3}, "double"},
+		{"exceeds int32 positive", []float64{1, 4000000000}, "uint32_t"},
+		{"exceeds int32 negative", []float64{-1, 4000000000}, "int64_t"},
+		{"exceeds uint32", []float64{1, 1 << 40}, "int64_t"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := numericCppType(tt.samples); got != tt.want {
+				t.Errorf("numericCppType(%v) = %q, want %q", tt.samples, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNumberFieldTypeForceFloats(t *testing.T) {
+	*forceFloats = true
+	defer func() { *forceFloats = false }()
+
+	if got := numberFieldType([]interface{}{1, 2, 3}); got != "double" {
+		t.Errorf("numberFieldType with -force-floats = %q, want %q", got, "double")
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// headerAndSource renders g as a header/source pair: headerPath's struct
+// definitions go in the header behind an include guard, and the
+// serialization helpers (if any) go in the companion source file, which
+// includes the header by its base name.
+func (g *generated) headerAndSource(headerPath, guardStyle string) (header []byte, source []byte) {
+	headerName := filepath.Base(headerPath)
+
+	body := g.structDefs
+	if *namespace != "" {
+		body = wrapNamespace(body)
+	}
+
+	var h strings.Builder
+	writeGuardOpen(&h, headerName, guardStyle)
+	h.WriteString(requiredIncludes(g.structDefs))
+	h.WriteString(body)
+	h.WriteString("\n")
+	writeGuardClose(&h, guardStyle)
+
+	var c strings.Builder
+	fmt.Fprintf(&c, "#include \"%s\"\n", headerName)
+	if g.serializers != "" {
+		c.WriteString(serializerInclude(*serializer))
+		serializers := g.serializers
+		if *namespace != "" {
+			serializers = wrapNamespace(serializers)
+		}
+		c.WriteString(serializers)
+	}
+
+	return []byte(h.String()), []byte(c.String())
+}
+
+// writeGuardOpen writes the opening half of a header include guard.
+func writeGuardOpen(b *strings.Builder, headerName, guardStyle string) {
+	if guardStyle == "ifndef" {
+		macro := guardMacro(headerName)
+		fmt.Fprintf(b, "#ifndef %s\n#define %s\n\n", macro, macro)
+		return
+	}
+	b.WriteString("#pragma once\n\n")
+}
+
+// writeGuardClose writes the closing half of a header include guard.
+func writeGuardClose(b *strings.Builder, guardStyle string) {
+	if guardStyle == "ifndef" {
+		b.WriteString("\n#endif\n")
+	}
+}
+
+// guardMacro derives the GENERATED_<NAME>_H macro name from a header's base
+// file name.
+func guardMacro(headerName string) string {
+	base := strings.TrimSuffix(headerName, filepath.Ext(headerName))
+	return fmt.Sprintf("GENERATED_%s_H", strings.ToUpper(sanitizeIdentifier(base)))
+}
+
+// requiredIncludes returns the standard library #include lines needed by
+// the types actually used in structDefs.
+func requiredIncludes(structDefs string) string {
+	var b strings.Builder
+	if strings.Contains(structDefs, "std::string") {
+		b.WriteString("#include <string>\n")
+	}
+	if strings.Contains(structDefs, "std::vector") {
+		b.WriteString("#include <vector>\n")
+	}
+	if strings.Contains(structDefs, "int32_t") || strings.Contains(structDefs, "int64_t") ||
+		strings.Contains(structDefs, "uint32_t") || strings.Contains(structDefs, "uint64_t") {
+		b.WriteString("#include <cstdint>\n")
+	}
+	if strings.Contains(structDefs, "std::optional") {
+		b.WriteString("#include <optional>\n")
+	}
+	if b.Len() > 0 {
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Parser decodes an input document into the generic value tree that
+// generateTypes walks to produce struct definitions.
+type Parser func(io.Reader) (interface{}, error)
+
+// parsersByFormat holds the Parser implementations selectable via -format.
+var parsersByFormat = map[string]Parser{
+	"json": ParseJSON,
+	"yaml": ParseYAML,
+}
+
+// ParseJSON decodes a JSON document.
+func ParseJSON(input io.Reader) (interface{}, error) {
+	var result interface{}
+	if err := json.NewDecoder(input).Decode(&result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ParseYAML decodes a YAML document, normalizing any map[interface{}]interface{}
+// produced by the decoder into map[string]interface{} so the rest of the
+// generator only ever has to deal with one map representation.
+func ParseYAML(input io.Reader) (interface{}, error) {
+	var result interface{}
+	if err := yaml.NewDecoder(input).Decode(&result); err != nil {
+		return nil, err
+	}
+	return normalizeYAML(result), nil
+}
+
+// normalizeYAML recursively rewrites map[interface{}]interface{} values as
+// map[string]interface{}, leaving everything else untouched.
+func normalizeYAML(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			out[fmt.Sprintf("%v", key)] = normalizeYAML(val)
+		}
+		return out
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			out[key] = normalizeYAML(val)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, val := range v {
+			out[i] = normalizeYAML(val)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// parseAuto sniffs the input format by trying JSON first and falling back to
+// YAML, since YAML is a superset of most things that aren't valid JSON.
+func parseAuto(input io.Reader) (interface{}, error) {
+	data, err := ioutil.ReadAll(input)
+	if err != nil {
+		return nil, err
+	}
+
+	if result, err := ParseJSON(bytes.NewReader(data)); err == nil {
+		return result, nil
+	}
+
+	result, err := ParseYAML(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("could not parse input as JSON or YAML: %s", err)
+	}
+	return result, nil
+}
+
+// parserForFormat resolves the -format flag value to a Parser.
+func parserForFormat(format string) (Parser, error) {
+	switch format {
+	case "auto":
+		return parseAuto, nil
+	case "":
+		return parseAuto, nil
+	default:
+		parse, ok := parsersByFormat[format]
+		if !ok {
+			return nil, fmt.Errorf("unknown format %q", format)
+		}
+		return parse, nil
+	}
+}
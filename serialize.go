@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// structField describes one emitted struct member, enough to round-trip it
+// through a JSON library's ADL hooks.
+type structField struct {
+	key     string // the original JSON/YAML key
+	name    string // the generated C++ field name
+	cppType string // the field's C++ type, e.g. "std::vector<Item>"
+}
+
+// structInfo describes one emitted struct, in the order its closing brace
+// was written, so serializers can be appended after every struct definition.
+type structInfo struct {
+	name   string
+	fields []structField
+}
+
+// uniqueStructName returns name unless a struct by that name has already
+// been emitted, in which case it returns name suffixed with an
+// incrementing integer until it finds one that hasn't. Every nested struct
+// is hoisted to the same flat, top-level list (see generateTypes), so two
+// unrelated fields that produce the same type name -- e.g. the same key
+// reused at two different nesting depths -- would otherwise collide into
+// two non-compiling definitions of the same struct name.
+func uniqueStructName(name string, structs *[]structInfo) string {
+	taken := make(map[string]bool, len(*structs))
+	for _, s := range *structs {
+		taken[s.name] = true
+	}
+	if !taken[name] {
+		return name
+	}
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s%d", name, i)
+		if !taken[candidate] {
+			return candidate
+		}
+	}
+}
+
+// cppPrimitiveType maps a decoded leaf value to its C++ scalar type.
+func cppPrimitiveType(value interface{}) string {
+	convert := cppTypeMapping[reflect.TypeOf(value).Name()]
+	if convert != "" {
+		return convert
+	}
+	return reflect.TypeOf(value).Name()
+}
+
+// emitSerializers renders the ADL to_json/from_json pair (or the RapidJSON
+// equivalent) for every struct collected during generation, in the order
+// they were emitted.
+func emitSerializers(kind string, structs []structInfo) string {
+	switch kind {
+	case "nlohmann":
+		return emitNlohmannSerializers(structs)
+	case "rapidjson":
+		return emitRapidjsonSerializers(structs)
+	default:
+		return ""
+	}
+}
+
+func emitNlohmannSerializers(structs []structInfo) string {
+	var b strings.Builder
+	for _, s := range structs {
+		fmt.Fprintf(&b, "\nvoid to_json(nlohmann::json& j, const %s& v) {\n", s.name)
+		for _, f := range s.fields {
+			fmt.Fprintf(&b, "\tj[\"%s\"] = v.%s;\n", f.key, f.name)
+		}
+		b.WriteString("}\n")
+
+		fmt.Fprintf(&b, "\nvoid from_json(const nlohmann::json& j, %s& v) {\n", s.name)
+		for _, f := range s.fields {
+			fmt.Fprintf(&b, "\tv.%s = j.at(\"%s\").get<decltype(v.%s)>();\n", f.name, f.key, f.name)
+		}
+		b.WriteString("}\n")
+	}
+	return b.String()
+}
+
+// emitRapidjsonSerializers renders hand-rolled encode/decode helpers in the
+// style commonly paired with RapidJSON, which has no ADL hook of its own.
+func emitRapidjsonSerializers(structs []structInfo) string {
+	var b strings.Builder
+	for _, s := range structs {
+		fmt.Fprintf(&b, "\ntemplate <typename Writer>\nvoid Encode(Writer& writer, const %s& v) {\n\twriter.StartObject();\n", s.name)
+		for _, f := range s.fields {
+			fmt.Fprintf(&b, "\twriter.Key(\"%s\");\n\tEncode(writer, v.%s);\n", f.key, f.name)
+		}
+		b.WriteString("\twriter.EndObject();\n}\n")
+
+		fmt.Fprintf(&b, "\nvoid Decode(const rapidjson::Value& j, %s& v) {\n", s.name)
+		for _, f := range s.fields {
+			fmt.Fprintf(&b, "\tDecode(j[\"%s\"], v.%s);\n", f.key, f.name)
+		}
+		b.WriteString("}\n")
+	}
+	return b.String()
+}
+
+// serializerInclude returns the #include line needed for the chosen
+// serializer, or "" if no serializer was requested.
+func serializerInclude(kind string) string {
+	switch kind {
+	case "nlohmann":
+		return "#include <nlohmann/json.hpp>\n"
+	case "rapidjson":
+		return "#include \"rapidjson/document.h\"\n#include \"rapidjson/writer.h\"\n"
+	default:
+		return ""
+	}
+}